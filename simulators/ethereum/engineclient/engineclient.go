@@ -0,0 +1,203 @@
+// Package engineclient provides a hardened engine-API client for simulators
+// that drive clients through the newPayload/forkchoiceUpdated interface, so
+// the JWT handling and per-client method negotiation live in one place. The
+// sync simulator is currently its only user.
+package engineclient
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	gnode "github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// defaultJWTSecretFile is where hive places the shared JWT secret for a
+// client's engine API, mirroring the file real consensus clients are pointed
+// at via --jwt-secret in CL<->EL setups.
+const defaultJWTSecretFile = "/jwtsecret"
+
+// fixedTestSecret is the JWT secret hivechain bakes into every client it
+// launches. Hive doesn't (yet) hand the simulator container itself a
+// secret/file the way it does a CL<->EL pair, so defaultJWTSecretFile and
+// HIVE_JWTSECRET_FILE are normally both absent here; this fixed value, which
+// every simulator hard-coded before engineclient existed, is what Dial
+// actually falls back on.
+var fixedTestSecret = [32]byte{
+	0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74,
+	0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74,
+	0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x65,
+}
+
+// candidateMethods lists every engine_* method version this client knows how
+// to call. It is advertised to engine_exchangeCapabilities so that, for each
+// method family, the newest version the remote client supports can be
+// selected automatically instead of being hard-coded per simulator.
+var candidateMethods = []string{
+	"engine_newPayloadV1", "engine_newPayloadV2", "engine_newPayloadV3", "engine_newPayloadV4",
+	"engine_forkchoiceUpdatedV1", "engine_forkchoiceUpdatedV2", "engine_forkchoiceUpdatedV3",
+	"engine_getPayloadV1", "engine_getPayloadV2", "engine_getPayloadV3", "engine_getPayloadV4",
+	"engine_getBlobsV1", "engine_getBlobsV2",
+}
+
+// Client is an authenticated connection to a client's engine API, with the
+// newest mutually supported method version pre-negotiated for each call.
+type Client struct {
+	rpc *rpc.Client
+
+	newPayloadMethod        string
+	forkchoiceUpdatedMethod string
+	getPayloadMethod        string
+}
+
+// Dial connects to the engine API at url, which may be an http(s):// or
+// ws(s):// endpoint, authenticates using the JWT secret chosen by
+// loadJWTSecret from jwtSecretFile, and negotiates method versions via
+// engine_exchangeCapabilities.
+func Dial(ctx context.Context, url, jwtSecretFile string) (*Client, error) {
+	secret, err := loadJWTSecret(jwtSecretFile)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := rpc.DialOptions(ctx, url, rpc.WithHTTPAuth(gnode.NewJWTAuth(secret)))
+	if err != nil {
+		return nil, fmt.Errorf("can't dial engine API at %s: %v", url, err)
+	}
+	c := &Client{rpc: rc}
+	if err := c.negotiate(ctx); err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// negotiate calls engine_exchangeCapabilities and records the newest
+// supported version of each method family this client needs.
+func (c *Client) negotiate(ctx context.Context) error {
+	var supported []string
+	if err := c.rpc.CallContext(ctx, &supported, "engine_exchangeCapabilities", candidateMethods); err != nil {
+		return fmt.Errorf("engine_exchangeCapabilities failed: %v", err)
+	}
+	have := make(map[string]bool, len(supported))
+	for _, m := range supported {
+		have[m] = true
+	}
+	c.newPayloadMethod = newestVersion(have, "engine_newPayloadV")
+	c.forkchoiceUpdatedMethod = newestVersion(have, "engine_forkchoiceUpdatedV")
+	c.getPayloadMethod = newestVersion(have, "engine_getPayloadV")
+	return nil
+}
+
+// newestVersion returns the highest-numbered method starting with prefix
+// that's present in have, or "" if none is.
+func newestVersion(have map[string]bool, prefix string) string {
+	best, bestVersion := "", 0
+	for m := range have {
+		if !strings.HasPrefix(m, prefix) {
+			continue
+		}
+		v, err := strconv.Atoi(strings.TrimPrefix(m, prefix))
+		if err == nil && v > bestVersion {
+			best, bestVersion = m, v
+		}
+	}
+	return best
+}
+
+// NewPayload delivers an execution payload using the newest engine_newPayload
+// version the client advertised support for.
+func (c *Client) NewPayload(ctx context.Context, params ...any) (engine.PayloadStatusV1, error) {
+	var resp engine.PayloadStatusV1
+	if c.newPayloadMethod == "" {
+		return resp, fmt.Errorf("client does not support any known engine_newPayload version")
+	}
+	err := c.rpc.CallContext(ctx, &resp, c.newPayloadMethod, params...)
+	return resp, err
+}
+
+// ForkchoiceUpdated updates the client's view of the canonical chain using
+// the newest engine_forkchoiceUpdated version the client advertised support
+// for.
+func (c *Client) ForkchoiceUpdated(ctx context.Context, params ...any) (engine.ForkChoiceResponse, error) {
+	var resp engine.ForkChoiceResponse
+	if c.forkchoiceUpdatedMethod == "" {
+		return resp, fmt.Errorf("client does not support any known engine_forkchoiceUpdated version")
+	}
+	err := c.rpc.CallContext(ctx, &resp, c.forkchoiceUpdatedMethod, params...)
+	return resp, err
+}
+
+// GetPayload retrieves a previously requested built payload using the newest
+// engine_getPayload version the client advertised support for.
+func (c *Client) GetPayload(ctx context.Context, id engine.PayloadID) (*engine.ExecutionPayloadEnvelope, error) {
+	if c.getPayloadMethod == "" {
+		return nil, fmt.Errorf("client does not support any known engine_getPayload version")
+	}
+	var resp engine.ExecutionPayloadEnvelope
+	err := c.rpc.CallContext(ctx, &resp, c.getPayloadMethod, id)
+	return &resp, err
+}
+
+// GetBlobsV1 retrieves blobs the client still holds in its pool by versioned
+// hash, via engine_getBlobsV1 specifically. Unlike NewPayload/ForkchoiceUpdated/
+// GetPayload, the get-blobs methods aren't interchangeable newer-vs-older
+// versions of the same call: V2 has a different response shape (it adds
+// cell proofs for PeerDAS), so calling the wrong one against a client that
+// only answers the other version is a hard error, not a silent downgrade.
+func (c *Client) GetBlobsV1(ctx context.Context, hashes []common.Hash) ([]*engine.BlobAndProofV1, error) {
+	var resp []*engine.BlobAndProofV1
+	err := c.rpc.CallContext(ctx, &resp, "engine_getBlobsV1", hashes)
+	return resp, err
+}
+
+// GetBlobsV2 retrieves blobs the client still holds in its pool by versioned
+// hash, via engine_getBlobsV2, whose response includes cell proofs for
+// PeerDAS.
+func (c *Client) GetBlobsV2(ctx context.Context, hashes []common.Hash) ([]*engine.BlobAndProofV2, error) {
+	var resp []*engine.BlobAndProofV2
+	err := c.rpc.CallContext(ctx, &resp, "engine_getBlobsV2", hashes)
+	return resp, err
+}
+
+// Close releases the underlying RPC connection.
+func (c *Client) Close() {
+	c.rpc.Close()
+}
+
+// loadJWTSecret reads the hex-encoded 32-byte JWT secret hive shares between
+// a client and the simulator driving its engine API, matching the file real
+// consensus clients are pointed at in CL<->EL setups. An empty path is
+// replaced by the HIVE_JWTSECRET_FILE env var, or defaultJWTSecretFile if
+// that is unset too; if the resulting file does not exist, it falls back to
+// fixedTestSecret rather than failing Dial outright.
+func loadJWTSecret(path string) ([32]byte, error) {
+	if path == "" {
+		path = os.Getenv("HIVE_JWTSECRET_FILE")
+	}
+	if path == "" {
+		path = defaultJWTSecretFile
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fixedTestSecret, nil
+		}
+		return [32]byte{}, fmt.Errorf("can't read jwt secret file %s: %v", path, err)
+	}
+	var secret [32]byte
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return secret, fmt.Errorf("invalid jwt secret encoding in %s: %v", path, err)
+	}
+	if len(raw) != len(secret) {
+		return secret, fmt.Errorf("jwt secret in %s must be %d bytes, got %d", path, len(secret), len(raw))
+	}
+	copy(secret[:], raw)
+	return secret, nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/hive/hivesim"
+)
+
+// benchmarkSizes are the hivechain-generated chain lengths exercised by the
+// benchmark subsuite. They range from small enough to run in every CI build
+// to large enough to surface sync performance regressions.
+var benchmarkSizes = []int{10_000, 100_000, 1_000_000}
+
+// benchDir is where hivechain lays out the benchmark chains, one directory
+// per chain length.
+const benchDir = "chain/bench"
+
+// benchEnabled reports whether the benchmark subsuite should run at all.
+// Plain correctness runs of this simulator never generate chain/bench, so
+// the subsuite stays opt-in rather than launching multi-size (up to 1M
+// block) sync runs unconditionally.
+func benchEnabled() bool {
+	info, err := os.Stat(benchDir)
+	return err == nil && info.IsDir()
+}
+
+// benchChainExists reports whether hivechain actually produced the chain for
+// a given benchmark size, so a single missing size doesn't fail the whole
+// subsuite at container start.
+func benchChainExists(size int) bool {
+	_, err := os.Stat(fmt.Sprintf("%s/%d/chain.rlp", benchDir, size))
+	return err == nil
+}
+
+// benchResult is the structured, machine-readable outcome of a single
+// (source, sink, mode, size) benchmark run. It is logged as one JSON line so
+// results can be aggregated across CI runs and diffed between client
+// versions.
+type benchResult struct {
+	Source          string  `json:"source"`
+	Sink            string  `json:"sink"`
+	Mode            string  `json:"mode"`
+	ChainLength     int     `json:"chainLength"`
+	ElapsedSeconds  float64 `json:"elapsedSeconds"`
+	BlocksPerSecond float64 `json:"blocksPerSecond"`
+	StatesPerSecond float64 `json:"stateEntriesPerSecond"`
+	PeakRSSBytes    uint64  `json:"peakRssBytes,omitempty"`
+}
+
+// benchSuite builds the sync-bench suite: unlike "sync", it does not assert
+// correctness beyond what checkSync already requires, it only measures how
+// fast and how heavy each (source, sink, mode) combination is.
+func benchSuite(params hivesim.Params) hivesim.Suite {
+	suite := hivesim.Suite{
+		Name: "sync-bench",
+		Description: `This suite benchmarks sync performance (time-to-head, throughput, peak memory)
+across sync modes and chain sizes, for each client acting as both sync source and sink.`,
+	}
+	for _, size := range benchmarkSizes {
+		size := size
+		if !benchChainExists(size) {
+			continue
+		}
+		suite.Add(hivesim.ClientTestSpec{
+			Role:        "eth1",
+			Name:        fmt.Sprintf("CLIENT as benchmark source (%d blocks)", size),
+			Description: fmt.Sprintf("Loads a %d-block test chain into the client to use as a benchmark sync source.", size),
+			Parameters:  params,
+			Files:       benchSourceFiles(size),
+			Run: func(t *hivesim.T, c *hivesim.Client) {
+				runBenchmarkSourceTest(t, c, params, size)
+			},
+		})
+	}
+	return suite
+}
+
+// benchSourceFiles and benchSinkFiles locate the per-size chains generated
+// by hivechain, laid out alongside the correctness suite's fixed-size chain.
+func benchSourceFiles(size int) map[string]string {
+	return map[string]string{
+		"genesis.json": fmt.Sprintf("./%s/%d/genesis.json", benchDir, size),
+		"chain.rlp":    fmt.Sprintf("./%s/%d/chain.rlp", benchDir, size),
+	}
+}
+
+func benchSinkFiles(size int) map[string]string {
+	return map[string]string{
+		"genesis.json": fmt.Sprintf("./%s/%d/genesis.json", benchDir, size),
+	}
+}
+
+func runBenchmarkSourceTest(t *hivesim.T, c *hivesim.Client, params hivesim.Params, chainLength int) {
+	source := &node{Client: c}
+	if err := source.checkHead(); err != nil {
+		t.Fatal(err)
+	}
+
+	enode, err := source.EnodeURL()
+	if err != nil {
+		t.Fatal("can't get node peer-to-peer endpoint:", enode)
+	}
+	sinkParams := params.Set("HIVE_BOOTNODE", enode)
+
+	for _, mode := range syncModes {
+		mode := mode
+		modeParams := sinkParamsForMode(sinkParams, mode)
+		t.RunAllClients(hivesim.ClientTestSpec{
+			Role:        "eth1",
+			Name:        fmt.Sprintf("benchmark %s -> CLIENT (%s, %d blocks)", source.Type, mode, chainLength),
+			Description: fmt.Sprintf("Measures time-to-head, throughput and peak memory while %s-syncing %d blocks from a %s node.", mode, chainLength, source.Type),
+			Parameters:  modeParams,
+			Files:       benchSinkFiles(chainLength),
+			Run: func(t *hivesim.T, c *hivesim.Client) {
+				runBenchmarkSinkTest(t, c, source.Type, mode, chainLength)
+			},
+		})
+	}
+}
+
+func runBenchmarkSinkTest(t *hivesim.T, c *hivesim.Client, sourceType, mode string, chainLength int) {
+	n := &node{Client: c}
+
+	stop := make(chan struct{})
+	peakRSS := make(chan uint64, 1)
+	go monitorPeakRSS(n, stop, peakRSS)
+
+	start := time.Now()
+	err := n.checkSync(t, mode)
+	elapsed := time.Since(start)
+	close(stop)
+	if err != nil {
+		t.Fatal("benchmark sync failed:", err)
+	}
+
+	head, err := n.head()
+	if err != nil {
+		t.Fatal("can't read final head for benchmark:", err)
+	}
+
+	result := benchResult{
+		Source:          sourceType,
+		Sink:            n.Type,
+		Mode:            mode,
+		ChainLength:     chainLength,
+		ElapsedSeconds:  elapsed.Seconds(),
+		BlocksPerSecond: float64(head.Number.Uint64()) / elapsed.Seconds(),
+		StatesPerSecond: float64(n.lastStateEntries) / elapsed.Seconds(),
+		PeakRSSBytes:    <-peakRSS,
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal("can't marshal benchmark result:", err)
+	}
+	t.Logf("BENCHMARK_RESULT %s", data)
+}
+
+// monitorPeakRSS polls the sink container's memory usage for the duration of
+// a benchmark run and, once stop is closed, sends the peak value observed on
+// result.
+// Collection errors are non-fatal: a benchmark that can't observe RSS still
+// reports timing and throughput.
+func monitorPeakRSS(n *node, stop <-chan struct{}, result chan<- uint64) {
+	var peak uint64
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			result <- peak
+			return
+		case <-ticker.C:
+			if rss, err := n.containerRSS(); err == nil && rss > peak {
+				peak = rss
+			}
+		}
+	}
+}
+
+// containerRSS reads the resident set size (VmRSS) of process 1 inside the
+// sink container, giving a peak-memory signal without requiring a dedicated
+// docker-stats API. Memory of any other process in the container is not
+// counted.
+func (n *node) containerRSS() (uint64, error) {
+	res, err := n.Exec("sh", "-c", "grep VmRSS /proc/1/status")
+	if err != nil {
+		return 0, err
+	}
+	if res.ExitCode != 0 {
+		return 0, fmt.Errorf("rss probe exited with code %d: %s", res.ExitCode, res.Stderr)
+	}
+	fields := strings.Fields(res.Stdout)
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("unexpected /proc/1/status output: %q", res.Stdout)
+	}
+	kb, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return kb * 1024, nil
+}
@@ -4,15 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
-	gnode "github.com/ethereum/go-ethereum/node"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/hive/hivesim"
+	"github.com/ethereum/hive/simulators/ethereum/engineclient"
 )
 
 var (
@@ -25,8 +30,29 @@ var (
 	sinkFiles = map[string]string{
 		"genesis.json": "./chain/genesis.json",
 	}
+
+	// syncModes are the sink-side sync modes exercised against every source.
+	// Not all clients support every mode; a sink that doesn't recognize the
+	// requested mode is expected to fall back to its default, and
+	// checkSyncMode only hard-fails on a mismatch for modes that have an
+	// observable signature in eth_syncing (currently just "snap").
+	syncModes = []string{"full", "snap", "light"}
 )
 
+// sinkParamsForMode sets the launch parameters that select a sink's sync
+// mode. HIVE_NODETYPE picks the node type ("full" or "light"); snap sync is
+// a full node launched with HIVE_SYNCMODE=snap, not a node type of its own.
+func sinkParamsForMode(params hivesim.Params, mode string) hivesim.Params {
+	switch mode {
+	case "light":
+		return params.Set("HIVE_NODETYPE", "light")
+	case "snap":
+		return params.Set("HIVE_NODETYPE", "full").Set("HIVE_SYNCMODE", "snap")
+	default:
+		return params.Set("HIVE_NODETYPE", "full").Set("HIVE_SYNCMODE", "full")
+	}
+}
+
 func main() {
 	// Load fork environment.
 	var params hivesim.Params
@@ -50,12 +76,40 @@ For each client, we test if it can serve as a sync source for all other clients
 			runSourceTest(t, c, params)
 		},
 	})
+	if checkpoint, err := loadCheckpoint(); err != nil {
+		panic(err)
+	} else if checkpoint != nil {
+		suite.Add(hivesim.ClientTestSpec{
+			Role:        "eth1",
+			Name:        "CLIENT as pruned sync source (checkpoint)",
+			Description: fmt.Sprintf("This loads the test chain into the client with history below block %d pruned, to act as a weak-subjectivity sync source.", checkpoint.Number),
+			// HIVE_CHAIN_PRUNE_BEFORE, like HIVE_NODETYPE and HIVE_SYNCMODE
+			// above, is consumed by the client's own entrypoint script, not
+			// by this simulator: it's the client's responsibility to prune
+			// its imported chain below this block before serving it.
+			Parameters: params.Set("HIVE_CHAIN_PRUNE_BEFORE", strconv.FormatUint(checkpoint.Number, 10)),
+			Files:       sourceFiles,
+			Run: func(t *hivesim.T, c *hivesim.Client) {
+				runCheckpointSourceTest(t, c, params, checkpoint)
+			},
+		})
+	}
+
 	hivesim.MustRunSuite(hivesim.New(), suite)
+
+	// The benchmark subsuite is opt-in: it only runs when benchmark chains
+	// were actually generated for this run, mirroring how loadCheckpoint
+	// gates the checkpoint variant on chain/checkpoint.json. Otherwise every
+	// plain correctness invocation of this simulator would also launch
+	// multi-size benchmark runs against chains that were never produced.
+	if benchEnabled() {
+		hivesim.MustRunSuite(hivesim.New(), benchSuite(params))
+	}
 }
 
 func runSourceTest(t *hivesim.T, c *hivesim.Client, params hivesim.Params) {
 	// Check whether the source has imported its chain.rlp correctly.
-	source := &node{c}
+	source := &node{Client: c}
 	if err := source.checkHead(); err != nil {
 		t.Fatal(err)
 	}
@@ -67,31 +121,52 @@ func runSourceTest(t *hivesim.T, c *hivesim.Client, params hivesim.Params) {
 	}
 	sinkParams := params.Set("HIVE_BOOTNODE", enode)
 
-	// Sync all sink nodes against the source.
-	t.RunAllClients(hivesim.ClientTestSpec{
-		Role:        "eth1",
-		Name:        fmt.Sprintf("sync %s -> CLIENT", source.Type),
-		Description: fmt.Sprintf("This test attempts to sync the chain from a %s node.", source.Type),
-		Parameters:  sinkParams,
-		Files:       sinkFiles,
-		Run:         runSyncTest,
-	})
+	// Sync all sink nodes against the source, once per sync mode.
+	for _, mode := range syncModes {
+		mode := mode
+		modeParams := sinkParamsForMode(sinkParams, mode)
+		t.RunAllClients(hivesim.ClientTestSpec{
+			Role:        "eth1",
+			Name:        fmt.Sprintf("sync %s -> CLIENT (%s)", source.Type, mode),
+			Description: fmt.Sprintf("This test attempts to %s-sync the chain from a %s node.", mode, source.Type),
+			Parameters:  modeParams,
+			Files:       sinkFiles,
+			Run: func(t *hivesim.T, c *hivesim.Client) {
+				runSyncTest(t, c, mode)
+			},
+		})
+	}
 }
 
-func runSyncTest(t *hivesim.T, c *hivesim.Client) {
-	node := &node{c}
-	err := node.checkSync(t)
+func runSyncTest(t *hivesim.T, c *hivesim.Client, mode string) {
+	node := &node{Client: c}
+	start := time.Now()
+	err := node.checkSync(t, mode)
+	elapsed := time.Since(start)
 	if err != nil {
 		t.Fatal("sync failed:", err)
 	}
+	t.Logf("%s sync of %s completed in %v", mode, node.Type, elapsed)
 }
 
 type node struct {
 	*hivesim.Client
+
+	// lastStateEntries is the most recent snap-sync "syncedAccounts" value
+	// observed via eth_syncing, used by the benchmark subsuite to report
+	// state-entries/sec without re-querying after the node has gone idle.
+	lastStateEntries uint64
+
+	// sawSyncingResponse and sawSnapProgress track what eth_syncing has
+	// reported across polls, so checkSyncMode can tell "never caught the
+	// node mid-sync" (e.g. a tiny conformance chain finishing between
+	// polls) apart from "caught it syncing via a non-snap code path".
+	sawSyncingResponse bool
+	sawSnapProgress    bool
 }
 
 // checkSync waits for the node to reach the head of the chain.
-func (n *node) checkSync(t *hivesim.T) error {
+func (n *node) checkSync(t *hivesim.T, mode string) error {
 	var expectedHead types.Header
 	err := common.LoadJSON("chain/headblock.json", &expectedHead)
 	if err != nil {
@@ -99,9 +174,13 @@ func (n *node) checkSync(t *hivesim.T) error {
 	}
 	wantHash := expectedHead.Hash()
 
-	if err := n.triggerSync(t); err != nil {
+	target, err := n.triggerSync(t)
+	if err != nil {
 		return err
 	}
+	if target.Head != (common.Hash{}) {
+		wantHash = target.Head
+	}
 
 	var (
 		timeout = time.After(syncTimeout)
@@ -112,6 +191,9 @@ func (n *node) checkSync(t *hivesim.T) error {
 		case <-timeout:
 			return fmt.Errorf("timeout (%v elapsed, current head is %d)", syncTimeout, current)
 		default:
+			if mode == "snap" {
+				n.logSnapProgress(t)
+			}
 			block, err := n.head()
 			if err != nil {
 				t.Logf("error getting block from %s (%s): %v", n.Type, n.Container, err)
@@ -125,6 +207,14 @@ func (n *node) checkSync(t *hivesim.T) error {
 				if block.Hash() != wantHash {
 					return fmt.Errorf("wrong head hash %x, want %x", block.Hash(), wantHash)
 				}
+				if err := n.checkSyncMode(mode); err != nil {
+					return err
+				}
+				if target.Finalized != (common.Hash{}) {
+					if err := n.checkFinalized(target.Finalized); err != nil {
+						return err
+					}
+				}
 				return nil // success
 			}
 			// check in a little while....
@@ -134,43 +224,211 @@ func (n *node) checkSync(t *hivesim.T) error {
 	}
 }
 
+// syncProgress mirrors the fields of eth_syncing that are specific to
+// snap-sync, so that snap-syncing sinks can be observed actually healing
+// state rather than just catching up on headers/bodies. SyncedAccounts and
+// HealedTrienodes/HealingBytecode are what modern, PoS-era snap sync
+// reports; PulledStates/KnownStates are the deprecated pre-merge fast-sync
+// counters. A reported PulledStates still counts as snap progress, so older
+// clients are not failed by checkSyncMode; KnownStates is decoded but unused.
+type syncProgress struct {
+	SyncedAccounts  *hexutil.Uint64 `json:"syncedAccounts"`
+	HealedTrienodes *hexutil.Uint64 `json:"healedTrienodes"`
+	HealingBytecode *hexutil.Uint64 `json:"healingBytecode"`
+	PulledStates    *hexutil.Uint64 `json:"pulledStates"`
+	KnownStates     *hexutil.Uint64 `json:"knownStates"`
+}
+
+// logSnapProgress polls eth_syncing and logs the snap-sync-specific progress
+// fields when the node reports any, giving visibility into whether a "snap"
+// mode sink is actually state-healing rather than full-syncing regardless.
+func (n *node) logSnapProgress(t *hivesim.T) {
+	var result json.RawMessage
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := n.RPC().CallContext(ctx, &result, "eth_syncing"); err != nil {
+		return
+	}
+	if string(result) == "false" {
+		return // not currently syncing; nothing to observe this round
+	}
+	n.sawSyncingResponse = true
+
+	var progress syncProgress
+	if err := json.Unmarshal(result, &progress); err != nil {
+		return
+	}
+	hasSnapSignal := progress.SyncedAccounts != nil || progress.HealedTrienodes != nil ||
+		progress.HealingBytecode != nil || progress.PulledStates != nil
+	if hasSnapSignal {
+		n.sawSnapProgress = true
+		t.Logf("%s snap-sync progress: syncedAccounts=%v healedTrienodes=%v healingBytecode=%v",
+			n.Type, progress.SyncedAccounts, progress.HealedTrienodes, progress.HealingBytecode)
+	}
+	if progress.SyncedAccounts != nil {
+		n.lastStateEntries = uint64(*progress.SyncedAccounts)
+	}
+}
+
+// checkSyncMode asserts the sink actually used the requested sync mode
+// rather than silently falling back to another one. "snap" is the only mode
+// with an observable signature in eth_syncing. It only hard-fails when we
+// caught the node mid-sync and none of those responses carried any of the
+// snap fields at all (a real sign of a different code path); if we never caught
+// it syncing — plausible for this suite's tiny conformance chain, which can
+// finish snap sync between polls — there's no signal either way, so we
+// don't fail.
+func (n *node) checkSyncMode(mode string) error {
+	if mode != "snap" {
+		return nil
+	}
+	if n.sawSyncingResponse && !n.sawSnapProgress {
+		return fmt.Errorf("%s reported sync progress with none of the snap-sync fields (syncedAccounts/healedTrienodes/healingBytecode); it may have fallen back to full sync", n.Type)
+	}
+	return nil
+}
+
 type rpcRequest struct {
 	Method string
 	Params []json.RawMessage
 }
 
-func (n *node) triggerSync(t *hivesim.T) error {
-	// Load the engine requests generated by hivechain.
-	var newpayload, fcu rpcRequest
-	if err := common.LoadJSON("chain/headnewpayload.json", &newpayload); err != nil {
-		return err
-	}
-	if err := common.LoadJSON("chain/headfcu.json", &fcu); err != nil {
-		return err
+// engineScriptDir, if it exists, holds an ordered sequence of engine-API
+// requests generated by hivechain (e.g. "0001-newPayload.json",
+// "0002-forkchoiceUpdated.json", ...) that together replay a staged sync:
+// incremental payload delivery, gaps and reorgs, not just one head
+// announcement. When the directory is absent, triggerSync falls back to the
+// legacy single newPayload/FCU pair.
+var engineScriptDir = "chain/engine"
+
+// engineRequest is one step of a staged sync script: a raw JSON-RPC engine
+// call plus how long to wait after the previous step before sending it, so
+// scripts can model realistic CL pacing instead of firing everything
+// back-to-back. A DelayMillis of 0 means defaultStagePacing; the first step
+// is sent without waiting, whatever its DelayMillis.
+type engineRequest struct {
+	rpcRequest
+	DelayMillis int `json:"delayMillis"`
+}
+
+const defaultStagePacing = 500 * time.Millisecond
+
+// syncTarget is what the final forkchoiceUpdated of a script commits the
+// sink to: the canonical head the node's own head query is expected to
+// match, and (separately) the finalized block it must know about. The two
+// can differ — that's exactly the out-of-order-finalization/reorg case
+// staged scripts exist to exercise — so they're checked independently
+// instead of collapsing to one "the" target hash.
+type syncTarget struct {
+	Head      common.Hash
+	Finalized common.Hash
+}
+
+// triggerSync replays the engine-API script against the node and returns the
+// sync target implied by the final forkchoiceUpdated call.
+func (n *node) triggerSync(t *hivesim.T) (syncTarget, error) {
+	requests, err := n.loadEngineScript()
+	if err != nil {
+		return syncTarget{}, err
 	}
 
-	// engine client setup
-	token := [32]byte{0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x65}
-	engineURL := fmt.Sprintf("http://%v:8551/", n.IP)
 	ctx := context.Background()
-	c, _ := rpc.DialOptions(ctx, engineURL, rpc.WithHTTPAuth(gnode.NewJWTAuth(token)))
+	engineURL := fmt.Sprintf("http://%v:8551/", n.IP)
+	ec, err := engineclient.Dial(ctx, engineURL, "")
+	if err != nil {
+		return syncTarget{}, err
+	}
+	defer ec.Close()
 
-	// deliver newPayload
-	t.Logf("%s: %s", newpayload.Method, newpayload.Params)
-	var npresp engine.PayloadStatusV1
-	if err := c.Call(&npresp, newpayload.Method, conv2any(newpayload.Params)...); err != nil {
-		return err
+	var lastFCUParams []json.RawMessage
+	for i, req := range requests {
+		if i > 0 {
+			delay := defaultStagePacing
+			if req.DelayMillis > 0 {
+				delay = time.Duration(req.DelayMillis) * time.Millisecond
+			}
+			time.Sleep(delay)
+		}
+		t.Logf("[%d/%d] %s: %s", i+1, len(requests), req.Method, req.Params)
+		switch {
+		case strings.HasPrefix(req.Method, "engine_newPayload"):
+			resp, err := ec.NewPayload(ctx, conv2any(req.Params)...)
+			if err != nil {
+				return syncTarget{}, err
+			}
+			t.Logf("response: %+v", resp)
+		case strings.HasPrefix(req.Method, "engine_forkchoiceUpdated"):
+			resp, err := ec.ForkchoiceUpdated(ctx, conv2any(req.Params)...)
+			if err != nil {
+				return syncTarget{}, err
+			}
+			t.Logf("response: %+v", resp)
+			lastFCUParams = req.Params
+		default:
+			return syncTarget{}, fmt.Errorf("unsupported engine method in script: %s", req.Method)
+		}
+	}
+	if lastFCUParams == nil {
+		return syncTarget{}, nil
 	}
-	t.Logf("response: %+v", npresp)
+	return finalSyncTarget(lastFCUParams)
+}
 
-	// deliver forkchoiceUpdated
-	t.Logf("%s: %s", fcu.Method, fcu.Params)
-	var fcuresp engine.ForkChoiceResponse
-	if err := c.Call(&fcuresp, fcu.Method, conv2any(fcu.Params)...); err != nil {
-		return err
+// finalSyncTarget extracts the head and finalized hashes from the
+// ForkchoiceStateV1 of the script's last forkchoiceUpdated call.
+func finalSyncTarget(params []json.RawMessage) (syncTarget, error) {
+	if len(params) == 0 {
+		return syncTarget{}, fmt.Errorf("forkchoiceUpdated script entry has no params")
 	}
-	t.Logf("response: %+v", fcuresp)
-	return nil
+	var state engine.ForkchoiceStateV1
+	if err := json.Unmarshal(params[0], &state); err != nil {
+		return syncTarget{}, fmt.Errorf("can't decode forkchoiceUpdated state: %v", err)
+	}
+	return syncTarget{Head: state.HeadBlockHash, Finalized: state.FinalizedBlockHash}, nil
+}
+
+// loadEngineScript loads the ordered engine-API requests to replay. If
+// engineScriptDir exists, its *.json files are read in name order. Otherwise
+// the legacy single newPayload/FCU pair is used.
+func (n *node) loadEngineScript() ([]engineRequest, error) {
+	entries, err := os.ReadDir(engineScriptDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return loadLegacyEngineScript()
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	requests := make([]engineRequest, 0, len(names))
+	for _, name := range names {
+		var req engineRequest
+		if err := common.LoadJSON(filepath.Join(engineScriptDir, name), &req); err != nil {
+			return nil, fmt.Errorf("can't load engine script %s: %v", name, err)
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+// loadLegacyEngineScript loads the single newPayload/FCU pair used before
+// staged, multi-payload sync scripts existed.
+func loadLegacyEngineScript() ([]engineRequest, error) {
+	var newpayload, fcu engineRequest
+	if err := common.LoadJSON("chain/headnewpayload.json", &newpayload); err != nil {
+		return nil, err
+	}
+	if err := common.LoadJSON("chain/headfcu.json", &fcu); err != nil {
+		return nil, err
+	}
+	return []engineRequest{newpayload, fcu}, nil
 }
 
 // checkHead checks whether the remote chain head matches the given values.
@@ -197,6 +455,19 @@ func (n *node) head() (*types.Header, error) {
 	return ethclient.NewClient(n.RPC()).HeaderByNumber(ctx, nil)
 }
 
+// checkFinalized asserts the sink knows about the given block, independent
+// of whether it's also the current head. This is what actually exercises
+// out-of-order finalization and reorg scripts: the finalized block a staged
+// script commits to can lag or diverge from canonical head for a while.
+func (n *node) checkFinalized(hash common.Hash) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := ethclient.NewClient(n.RPC()).HeaderByHash(ctx, hash); err != nil {
+		return fmt.Errorf("sink does not have finalized block %x: %v", hash, err)
+	}
+	return nil
+}
+
 func conv2any[T any](s []T) []any {
 	cpy := make([]any, len(s))
 	for i := range s {